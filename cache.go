@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var scrapeCacheTTL = flag.Duration("scrape.cache-ttl", 0, "Serve the metrics of the previous scrape if it is more recent than this. 0 disables the cache.")
+
+// cachedCollector wraps a prometheus.Collector and replays the metrics of its
+// last collection for ttl, so that several Prometheus servers scraping the
+// exporter don't each query the database.
+type cachedCollector struct {
+	prometheus.Collector
+	ttl time.Duration
+
+	mu        sync.Mutex
+	collected time.Time
+	metrics   []prometheus.Metric
+}
+
+func newCachedCollector(c prometheus.Collector, ttl time.Duration) *cachedCollector {
+	return &cachedCollector{Collector: c, ttl: ttl}
+}
+
+// Collect implements prometheus.Collector.
+func (c *cachedCollector) Collect(ch chan<- prometheus.Metric) {
+	// Holding the lock while collecting lets concurrent scrapes wait for and
+	// share a single collection.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.collected) >= c.ttl {
+		metricCh := make(chan prometheus.Metric)
+		doneCh := make(chan struct{})
+		var metrics []prometheus.Metric
+		go func() {
+			for m := range metricCh {
+				metrics = append(metrics, m)
+			}
+			close(doneCh)
+		}()
+		c.Collector.Collect(metricCh)
+		close(metricCh)
+		<-doneCh
+		c.metrics = metrics
+		c.collected = time.Now()
+	}
+	for _, m := range c.metrics {
+		ch <- m
+	}
+}
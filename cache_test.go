@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// countingCollector exports the number of times it was collected.
+type countingCollector struct {
+	mu          sync.Mutex
+	collections int
+}
+
+var collectionsDesc = prometheus.NewDesc("collections", "Number of collections.", nil, nil)
+
+func (c *countingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collectionsDesc
+}
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	c.collections++
+	n := c.collections
+	c.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(collectionsDesc, prometheus.GaugeValue, float64(n))
+}
+
+func TestCachedCollector(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+	c := newCachedCollector(&countingCollector{}, ttl)
+	begun := time.Now()
+	first := gather(t, uncheckedCollector{c})
+	if want := "gauge collections{} 1"; len(first) != 1 || first[0] != want {
+		t.Fatalf("got %q, want %q", first, want)
+	}
+
+	// Concurrent scrapes within the TTL are served the cached metrics.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := gather(t, uncheckedCollector{c}); len(got) != 1 || got[0] != first[0] {
+				t.Errorf("within the TTL got %q, want the cached %q", got, first)
+			}
+		}()
+	}
+	wg.Wait()
+	if time.Since(begun) >= ttl {
+		t.Skip("the scrapes took longer than the TTL")
+	}
+
+	time.Sleep(ttl - time.Since(begun))
+	if got, want := gather(t, uncheckedCollector{c}), "gauge collections{} 2"; len(got) != 1 || got[0] != want {
+		t.Errorf("after the TTL got %q, want %q", got, want)
+	}
+}
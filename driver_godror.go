@@ -0,0 +1,19 @@
+//go:build godror
+// +build godror
+
+package main
+
+import (
+	"strings"
+
+	_ "github.com/godror/godror"
+)
+
+// driverName is the database/sql driver used to connect to Oracle DB.
+const driverName = "godror"
+
+// dsnWithRole returns dsn with the given administrative privilege (sysdba or
+// sysoper) appended as an "AS SYSDBA" style suffix.
+func dsnWithRole(dsn, role string) (string, error) {
+	return dsn + " AS " + strings.ToUpper(role), nil
+}
@@ -0,0 +1,36 @@
+//go:build godror
+// +build godror
+
+package main
+
+import (
+	"testing"
+
+	"github.com/godror/godror/dsn"
+)
+
+func TestDSNWithRole(t *testing.T) {
+	tests := []struct {
+		role            string
+		sysDBA, sysOper bool
+	}{
+		{role: "sysdba", sysDBA: true},
+		{role: "sysoper", sysOper: true},
+	}
+	for _, test := range tests {
+		s, err := dsnWithRole("sys/oracle@localhost:1521/ORCL", test.role)
+		if err != nil {
+			t.Fatalf("%s: %v", test.role, err)
+		}
+		params, err := dsn.Parse(s)
+		if err != nil {
+			t.Fatalf("%s: parsing %q: %v", test.role, s, err)
+		}
+		if params.IsSysDBA != test.sysDBA || params.IsSysOper != test.sysOper {
+			t.Errorf("%s: %q parses as sysdba %v sysoper %v", test.role, s, params.IsSysDBA, params.IsSysOper)
+		}
+		if params.Username != "sys" || params.ConnectString != "localhost:1521/ORCL" {
+			t.Errorf("%s: %q parses as user %q connect string %q", test.role, s, params.Username, params.ConnectString)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !godror
+// +build !godror
+
+package main
+
+import (
+	"fmt"
+
+	_ "github.com/mattn/go-oci8"
+)
+
+// driverName is the database/sql driver used to connect to Oracle DB.
+const driverName = "oci8"
+
+// dsnWithRole fails as the vendored go-oci8 ignores administrative privileges
+// in the DSN and would silently connect as a normal user.
+func dsnWithRole(dsn, role string) (string, error) {
+	return "", fmt.Errorf("connecting as %s needs the godror driver, build with -tags godror", role)
+}
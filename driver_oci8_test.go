@@ -0,0 +1,14 @@
+//go:build !godror
+// +build !godror
+
+package main
+
+import "testing"
+
+func TestDSNWithRole(t *testing.T) {
+	for _, role := range []string{"sysdba", "sysoper"} {
+		if dsn, err := dsnWithRole("sys/oracle@localhost:1521/ORCL", role); err == nil {
+			t.Errorf("%s: got DSN %q, want an error as go-oci8 ignores the role", role, dsn)
+		}
+	}
+}
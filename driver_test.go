@@ -0,0 +1,37 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestOpenDBDriver(t *testing.T) {
+	registered := false
+	for _, name := range sql.Drivers() {
+		registered = registered || name == driverName
+	}
+	if !registered {
+		t.Skipf("driver %s is not linked in", driverName)
+	}
+	want, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer want.Close()
+	sqlDriver = driverName
+	defer func() { sqlDriver = "fake" }()
+
+	for _, pdb := range []string{"", "SALES"} {
+		*databasePDB = pdb
+		db, err := openDB("system/oracle@localhost:1521/ORCL")
+		*databasePDB = ""
+		if err != nil {
+			t.Fatalf("pdb %q: %v", pdb, err)
+		}
+		if got := reflect.TypeOf(db.Driver()); got != reflect.TypeOf(want.Driver()) {
+			t.Errorf("pdb %q: driver %v, want the %s driver %v", pdb, got, driverName, reflect.TypeOf(want.Driver()))
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	databaseHost         = flag.String("database.host", "", "Database host to connect to when DATA_SOURCE_NAME is not set.")
+	databasePort         = flag.Int("database.port", 1521, "Database listener port to connect to with database.host.")
+	databaseService      = flag.String("database.service", "", "Database service name to connect to with database.host.")
+	databaseUser         = flag.String("database.user", "", "Database user to connect as with database.host.")
+	databasePasswordFile = flag.String("database.password-file", "", "File containing the password of database.user.")
+)
+
+// dsnFromFlags builds an easy connect DSN of the form
+// user/password@host:port/service from the database.host, database.port,
+// database.service, database.user and database.password-file flags. Without a
+// user the DSN starts at the @, for the credentials to be added from Vault.
+func dsnFromFlags() (string, error) {
+	if *databaseService == "" {
+		return "", fmt.Errorf("database.service is required with database.host")
+	}
+	var credentials string
+	if *databaseUser != "" {
+		credentials = *databaseUser
+		if *databasePasswordFile != "" {
+			password, err := os.ReadFile(*databasePasswordFile)
+			if err != nil {
+				return "", err
+			}
+			credentials += "/" + strings.TrimRight(string(password), "\r\n")
+		}
+	}
+	addr := net.JoinHostPort(*databaseHost, strconv.Itoa(*databasePort))
+	return credentials + "@" + addr + "/" + *databaseService, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDSNFromFlags(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("s3cret\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  string
+		err   string
+	}{
+		{
+			name:  "user and password",
+			flags: map[string]string{"database.host": "dbhost", "database.service": "ORCL", "database.user": "system", "database.password-file": passwordFile},
+			want:  "system/s3cret@dbhost:1521/ORCL",
+		},
+		{
+			name:  "user without password",
+			flags: map[string]string{"database.host": "dbhost", "database.port": "1522", "database.service": "ORCL", "database.user": "system"},
+			want:  "system@dbhost:1522/ORCL",
+		},
+		{
+			name:  "no user",
+			flags: map[string]string{"database.host": "dbhost", "database.service": "ORCL"},
+			want:  "@dbhost:1521/ORCL",
+		},
+		{
+			name:  "IPv6 host",
+			flags: map[string]string{"database.host": "::1", "database.service": "ORCL"},
+			want:  "@[::1]:1521/ORCL",
+		},
+		{
+			name:  "no service",
+			flags: map[string]string{"database.host": "dbhost"},
+			err:   "database.service is required",
+		},
+		{
+			name:  "missing password file",
+			flags: map[string]string{"database.host": "dbhost", "database.service": "ORCL", "database.user": "system", "database.password-file": filepath.Join(dir, "missing")},
+			err:   "no such file",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"database.host", "database.port", "database.service", "database.user", "database.password-file"} {
+				value, ok := test.flags[name]
+				if !ok && name == "database.port" {
+					value = "1521"
+				}
+				setFlag(t, name, value)
+			}
+			got, err := dsnFromFlags()
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+	sqlDriver = "fake"
+	// Keep the errors the tests provoke out of their output.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// fakeQuery is the canned result of the queries containing match.
+type fakeQuery struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+	// err fails the query, rowsErr the iteration after the last row.
+	err, rowsErr error
+}
+
+// fakeDB is a database answering every query with the first fakeQuery
+// matching it. Queries matching none fail with ORA-00942.
+type fakeDB struct {
+	queries []fakeQuery
+	// openPanic makes opening a connection panic with this value.
+	openPanic interface{}
+
+	mu sync.Mutex
+	// openErr fails opening connections, see SetOpenErr.
+	openErr  error
+	opens    int
+	executed []string
+}
+
+// fakeDBs maps the DSNs of the fake driver to their databases.
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+// registerFakeDB makes db reachable with the fake driver and returns its DSN.
+func registerFakeDB(t *testing.T, db *fakeDB) string {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	dsn := fmt.Sprintf("fake/%s@%d", t.Name(), len(fakeDBs))
+	fakeDBs[dsn] = db
+	return dsn
+}
+
+// openFakeDB opens a connection pool to a fake database answering queries.
+func openFakeDB(t *testing.T, queries ...fakeQuery) *sql.DB {
+	db, err := sql.Open("fake", registerFakeDB(t, &fakeDB{queries: queries}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Opens returns the number of connections opened to db.
+func (db *fakeDB) Opens() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.opens
+}
+
+// SetOpenErr makes opening connections to db fail with err from now on.
+func (db *fakeDB) SetOpenErr(err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.openErr = err
+}
+
+// Executed returns the queries run against db.
+func (db *fakeDB) Executed() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.executed...)
+}
+
+type fakeDriver struct{}
+
+// Open implements driver.Driver.
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	db := fakeDBs[dsn]
+	fakeDBsMu.Unlock()
+	if db == nil {
+		return nil, fmt.Errorf("ORA-12154: TNS:could not resolve the connect identifier specified")
+	}
+	if db.openPanic != nil {
+		panic(db.openPanic)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.openErr != nil {
+		return nil, db.openErr
+	}
+	db.opens++
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.Query(args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	s.db.executed = append(s.db.executed, s.query)
+	s.db.mu.Unlock()
+	for _, q := range s.db.queries {
+		if !strings.Contains(s.query, q.match) {
+			continue
+		}
+		if q.err != nil {
+			return nil, q.err
+		}
+		return &fakeRows{query: q}, nil
+	}
+	return nil, fmt.Errorf("ORA-00942: table or view does not exist")
+}
+
+type fakeRows struct {
+	query fakeQuery
+	next  int
+}
+
+func (r *fakeRows) Columns() []string { return r.query.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.query.rows) {
+		if r.query.rowsErr != nil {
+			return r.query.rowsErr
+		}
+		return io.EOF
+	}
+	copy(dest, r.query.rows[r.next])
+	r.next++
+	return nil
+}
+
+// scrapeCollector runs the scrape function of a collector against db.
+type scrapeCollector struct {
+	scrape func(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error
+	db     *sql.DB
+	err    error
+}
+
+// Describe implements prometheus.Collector.
+func (c *scrapeCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.err = c.scrape(context.Background(), c.db, ch)
+}
+
+// gather collects c with a pedantic registry, which also checks the
+// consistency of the metrics, and returns them formatted by sampleString.
+func gather(t *testing.T, c prometheus.Collector) []string {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples []string
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			samples = append(samples, sampleString(mf, m))
+		}
+	}
+	return samples
+}
+
+// sampleString formats m as "<type> name{label="value",...} value", the labels
+// being sorted by name.
+func sampleString(mf *dto.MetricFamily, m *dto.Metric) string {
+	var labels []string
+	for _, l := range m.GetLabel() {
+		labels = append(labels, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+	}
+	sort.Strings(labels)
+	var value float64
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		value = m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		value = m.GetGauge().GetValue()
+	case dto.MetricType_UNTYPED:
+		value = m.GetUntyped().GetValue()
+	case dto.MetricType_HISTOGRAM:
+		value = float64(m.GetHistogram().GetSampleCount())
+	case dto.MetricType_SUMMARY:
+		value = float64(m.GetSummary().GetSampleCount())
+	}
+	return fmt.Sprintf("%s %s{%s} %v", strings.ToLower(mf.GetType().String()), mf.GetName(), strings.Join(labels, ","), value)
+}
+
+// setFlag sets the flag name to value for the duration of the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	f := flag.Lookup(name)
+	if f == nil {
+		t.Fatalf("no flag %s", name)
+	}
+	old := f.Value.String()
+	if err := f.Value.Set(value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Value.Set(old) })
+}
+
+// fakeUp answers the up query of the scrapes.
+var fakeUp = fakeQuery{match: "FROM DUAL", columns: []string{"1"}, rows: [][]driver.Value{{int64(1)}}}
+
+// setCollectors replaces the collectors for the duration of the test.
+func setCollectors(t *testing.T, cs ...collector) {
+	old := collectors
+	for i := range cs {
+		if cs[i].timeout == nil {
+			cs[i].timeout = new(time.Duration)
+		}
+		if cs[i].refresh == nil {
+			cs[i].refresh = new(time.Duration)
+		}
+	}
+	collectors = cs
+	t.Cleanup(func() { collectors = old })
+}
+
+// uncheckedCollector hides the descriptors of a collector so that gathering
+// it collects it only once.
+type uncheckedCollector struct {
+	prometheus.Collector
+}
+
+// Describe implements prometheus.Collector.
+func (uncheckedCollector) Describe(chan<- *prometheus.Desc) {}
+
+// checkSamples reports the samples of want missing from got.
+func checkSamples(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	have := map[string]bool{}
+	for _, s := range got {
+		have[s] = true
+	}
+	for _, s := range want {
+		if !have[s] {
+			t.Errorf("missing %s in\n%s", s, strings.Join(got, "\n"))
+		}
+	}
+}
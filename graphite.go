@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/graphite"
+)
+
+var (
+	graphiteAddress  = flag.String("output.graphite-address", "", "Graphite carbon plaintext endpoint in the form host:port to push the metrics to, in addition to serving them. Disabled if empty.")
+	graphiteInterval = flag.Duration("output.graphite-interval", time.Minute, "Interval at which the metrics are pushed to Graphite.")
+	graphitePrefix   = flag.String("output.graphite-prefix", "oracledb", "Prefix of the Graphite paths of the metrics.")
+)
+
+// graphiteLogger logs the errors of the Graphite bridge.
+type graphiteLogger struct{}
+
+func (graphiteLogger) Println(v ...interface{}) {
+	slog.Error(fmt.Sprint(v...), "address", *graphiteAddress)
+}
+
+// pushGraphite pushes the metrics gathered by g to output.graphite-address
+// every output.graphite-interval until ctx is done. With the default prefix, a
+// metric such as
+//
+//	oracledb_tablespace_bytes{tablespace="USERS",type="PERMANENT"}
+//
+// is pushed as
+//
+//	oracledb.oracledb_tablespace_bytes.tablespace.USERS.type.PERMANENT
+func pushGraphite(ctx context.Context, g prometheus.Gatherer) error {
+	bridge, err := graphite.NewBridge(&graphite.Config{
+		URL:           *graphiteAddress,
+		Gatherer:      g,
+		Prefix:        *graphitePrefix,
+		Interval:      *graphiteInterval,
+		Logger:        graphiteLogger{},
+		ErrorHandling: graphite.ContinueOnError,
+	})
+	if err != nil {
+		return err
+	}
+	go bridge.Run(ctx)
+	return nil
+}
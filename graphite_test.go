@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPushGraphite(t *testing.T) {
+	carbon, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { carbon.Close() })
+	lines := make(chan string)
+	go func() {
+		for {
+			conn, err := carbon.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+
+	reg := prometheus.NewRegistry()
+	bytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "oracledb_tablespace_bytes",
+		Help: "Tablespace bytes.",
+	}, []string{"tablespace", "type"})
+	bytes.WithLabelValues("USERS", "PERMANENT").Set(1024)
+	reg.MustRegister(bytes)
+	setFlag(t, "output.graphite-address", carbon.Addr().String())
+	setFlag(t, "output.graphite-interval", "10ms")
+	// Stop the bridge before the flags are restored.
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := pushGraphite(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "oracledb.oracledb_tablespace_bytes.tablespace.USERS.type.PERMANENT 1024 "
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.HasPrefix(line, want) {
+				return
+			}
+			t.Logf("ignoring %q", line)
+		case <-timeout:
+			t.Fatalf("no line starting with %q pushed", want)
+		}
+	}
+}
+
+func TestPushGraphiteNoAddress(t *testing.T) {
+	setFlag(t, "output.graphite-address", "")
+	err := pushGraphite(context.Background(), prometheus.NewRegistry())
+	if err == nil {
+		t.Error("got no error without an address")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestHelp checks that every collector's metrics take their help text from a
+// constant naming the view they are read from.
+func TestHelp(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helps := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if !strings.HasSuffix(name.Name, "Help") {
+					continue
+				}
+				helps[name.Name] = true
+				lit, ok := n.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Errorf("%s: %s is not a string literal", fset.Position(name.Pos()), name.Name)
+					continue
+				}
+				help, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Deprecated metrics append the replacing query.
+				if !strings.Contains(help, " from ") || !strings.Contains(help, " in Oracle.") {
+					t.Errorf("%s: %s %q doesn't say \"from <view> in Oracle.\"", fset.Position(name.Pos()), name.Name, help)
+				}
+			}
+		case *ast.FuncDecl:
+			// The help text newBytesDesc passes on is checked at its calls.
+			return n.Name.Name != "newBytesDesc"
+		case *ast.CallExpr:
+			var name string
+			switch fun := n.Fun.(type) {
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			case *ast.Ident:
+				name = fun.Name
+			}
+			if name != "NewDesc" && name != "newBytesDesc" || len(n.Args) < 2 {
+				return true
+			}
+			switch help := n.Args[1].(type) {
+			case *ast.Ident:
+				if !strings.HasSuffix(help.Name, "Help") {
+					t.Errorf("%s: help text %s is not a Help constant", fset.Position(help.Pos()), help.Name)
+				}
+			case *ast.BinaryExpr:
+				// The help text of a custom gauge is its query.
+			default:
+				t.Errorf("%s: help text is not a Help constant", fset.Position(help.Pos()))
+			}
+		}
+		return true
+	})
+	if len(helps) == 0 {
+		t.Error("no Help constants found")
+	}
+}
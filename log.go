@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// levelFatal is logged right before the exporter exits. slog has no level of
+// its own for it.
+const levelFatal = slog.LevelError + 4
+
+var (
+	logLevel  = &levelFlag{}
+	logFormat = flag.String("log.format", "logfmt", "Output format of log messages, one of: [logfmt, json].")
+)
+
+func init() {
+	flag.Var(logLevel, "log.level", "Only log messages with the given severity or above. Valid levels: [debug, info, warn, error, fatal].")
+}
+
+// levelFlag is a flag.Value setting the minimum level of logged messages.
+type levelFlag struct {
+	slog.LevelVar
+}
+
+func (l *levelFlag) String() string {
+	if l.Level() == levelFatal {
+		return "fatal"
+	}
+	return strings.ToLower(l.Level().String())
+}
+
+func (l *levelFlag) Set(s string) error {
+	switch s {
+	case "debug":
+		l.LevelVar.Set(slog.LevelDebug)
+	case "info":
+		l.LevelVar.Set(slog.LevelInfo)
+	case "warn":
+		l.LevelVar.Set(slog.LevelWarn)
+	case "error":
+		l.LevelVar.Set(slog.LevelError)
+	case "fatal":
+		l.LevelVar.Set(levelFatal)
+	default:
+		return fmt.Errorf("unrecognized log level %q", s)
+	}
+	return nil
+}
+
+// newLogHandler returns a slog.Handler writing to w in the format selected by
+// the log.format flag.
+func newLogHandler(w io.Writer) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{
+		Level: logLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == levelFatal {
+				a.Value = slog.StringValue("FATAL")
+			}
+			return a
+		},
+	}
+	switch *logFormat {
+	case "logfmt":
+		return slog.NewTextHandler(w, opts), nil
+	case "json":
+		return slog.NewJSONHandler(w, opts), nil
+	}
+	return nil, fmt.Errorf("unrecognized log format %q", *logFormat)
+}
+
+// fatal logs msg at fatal level and exits.
+func fatal(msg string, args ...any) {
+	slog.Log(context.Background(), levelFatal, msg, args...)
+	os.Exit(1)
+}
+
+// loggerKey is the context key of the logger of a scrape.
+type loggerKey struct{}
+
+// withLogger returns a copy of ctx carrying logger, which the collectors run
+// with ctx log with.
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFrom returns the logger carried by ctx, or the default logger if there
+// is none.
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
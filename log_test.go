@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRunCollectorLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})).With("scrape_id", "cafe")
+	timeout := time.Duration(0)
+	c := collector{
+		name: "logging",
+		scrape: func(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+			loggerFrom(ctx).Info("Collecting")
+			panic("boom")
+		},
+		timeout: &timeout,
+	}
+	if err := NewExporter("").runCollector(withLogger(context.Background(), logger), c, nil, nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got error %v, want the panic", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got log\n%s\nwant the collector's message and the panic", buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "scrape_id=cafe collector=logging") {
+			t.Errorf("log line %q lacks the scrape's attributes", line)
+		}
+	}
+}
+
+func TestLoggerFromDefault(t *testing.T) {
+	if got := loggerFrom(context.Background()); got != slog.Default() {
+		t.Errorf("got %v, want the default logger", got)
+	}
+}
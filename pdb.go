@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var databasePDB = flag.String("database.pdb", "", "Pluggable database to switch every connection to, so that all collectors report on that PDB only. Defaults to the container connected to.")
+
+// pdbConnector opens connections with the driver and switches them to the
+// pluggable database of database.pdb.
+type pdbConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect implements driver.Connector.
+func (c *pdbConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := switchPDB(conn, *databasePDB); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Driver implements driver.Connector.
+func (c *pdbConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// openPDB opens a connection pool whose connections are switched to
+// database.pdb.
+func openPDB(dsn string) (*sql.DB, error) {
+	// sql.Open only looks up the driver.
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+	return sql.OpenDB(&pdbConnector{dsn: dsn, driver: drv}), nil
+}
+
+// switchPDB switches conn to the pluggable database pdb, which has to be open.
+// pdb is validated to be an identifier when parsing the flags.
+func switchPDB(conn driver.Conn, pdb string) error {
+	stmt, err := conn.Prepare("ALTER SESSION SET CONTAINER = " + pdb)
+	if err != nil {
+		return err
+	}
+	_, err = stmt.Exec(nil)
+	stmt.Close()
+	if err != nil {
+		return fmt.Errorf("switching to PDB %s: %v", pdb, err)
+	}
+
+	// Within a PDB, v$pdbs only has the PDB itself.
+	stmt, err = conn.Prepare("SELECT open_mode FROM v$pdbs")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	rows, err := stmt.Query(nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("PDB %s not found", pdb)
+		}
+		return err
+	}
+	if openMode := fmt.Sprintf("%s", dest[0]); !strings.HasPrefix(openMode, "READ") {
+		return fmt.Errorf("PDB %s is not open, its open mode is %s", pdb, openMode)
+	}
+	return nil
+}
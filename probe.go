@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var autoDisableCollectors = flag.Bool("collector.auto-disable-on-missing-privilege", false, "Disable the collectors that can't read their views when probed at startup.")
+
+// isMissingPrivilege reports whether err means that the exporter's user can't
+// read a view or call a package.
+func isMissingPrivilege(err error) bool {
+	return isMissingView(err) || strings.Contains(err.Error(), "ORA-01031")
+}
+
+// probingKey is the context key marking the collector runs of the privilege
+// probe.
+type probingKey struct{}
+
+// skipMissingView reports whether a collector should treat err as a view it
+// can't read and succeed without its metrics, e.g. a view of an unlicensed
+// pack. While probed, collectors fail instead, for the probe to see the view.
+func skipMissingView(ctx context.Context, err error) bool {
+	return isMissingView(err) && ctx.Value(probingKey{}) == nil
+}
+
+// probePrivileges runs every enabled collector once, discarding its metrics,
+// and logs which of them lack the privileges to read their views. With
+// collector.auto-disable-on-missing-privilege, those collectors are disabled.
+// It has to be called before the exporter is registered.
+func (e *Exporter) probePrivileges() {
+	db, err := e.connect()
+	if err == nil {
+		defer e.release(db)
+		err = ping(db)
+	}
+	if err != nil {
+		slog.Warn("Skipping privilege probe, database is unreachable", "dsn", e.maskedDSN(), "err", err)
+		return
+	}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		for range ch {
+		}
+	}()
+	defer close(ch)
+
+	var missing []string
+	for i, c := range collectors {
+		if !c.enabled() {
+			continue
+		}
+		err := e.runCollector(context.WithValue(context.Background(), probingKey{}, true), c, db, ch)
+		if err == nil || !isMissingPrivilege(err) {
+			continue
+		}
+		slog.Debug("Collector lacks privileges", "collector", c.name, "err", err)
+		missing = append(missing, c.name)
+		if *autoDisableCollectors {
+			collectors[i].disabled = true
+		}
+	}
+	if len(missing) > 0 {
+		slog.Warn("Collectors can't read their views", "collectors", strings.Join(missing, ","), "disabled", *autoDisableCollectors)
+	}
+}
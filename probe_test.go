@@ -0,0 +1,39 @@
+package main
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestProbePrivileges(t *testing.T) {
+	for _, autoDisable := range []string{"false", "true"} {
+		t.Run("collector.auto-disable-on-missing-privilege="+autoDisable, func(t *testing.T) {
+			setFlag(t, "collector.auto-disable-on-missing-privilege", autoDisable)
+			// awr_snapshot skips dba_hist_snapshot when scraped, which the fake
+			// database lacks.
+			setCollectors(t,
+				collector{name: "awr_snapshot", scrape: ScrapeAWRSnapshot},
+				collector{name: "wait_class", scrape: ScrapeWaitClass},
+			)
+			e := NewExporter(registerFakeDB(t, &fakeDB{queries: []fakeQuery{fakeUp, {
+				match:   "v$system_wait_class",
+				columns: []string{"WAIT_CLASS", "TOTAL_WAITS", "TIME_WAITED"},
+				rows:    [][]driver.Value{{"Commit", int64(7), 0.25}},
+			}}}))
+			e.probePrivileges()
+
+			wantDisabled := autoDisable == "true"
+			if collectors[0].disabled != wantDisabled {
+				t.Errorf("got awr_snapshot disabled %v, want %v", collectors[0].disabled, wantDisabled)
+			}
+			if collectors[1].disabled {
+				t.Error("got wait_class disabled, want it enabled")
+			}
+			// Scrapes still skip the missing view.
+			checkSamples(t, gather(t, uncheckedCollector{e}),
+				`gauge oracledb_up{} 1`,
+				`gauge oracledb_exporter_last_scrape_error{} 0`,
+			)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// startRefreshers starts running every enabled collector with a refresh
+// interval in the background. Scrapes serve the metrics of its last run
+// instead of running it.
+func (e *Exporter) startRefreshers() {
+	e.refreshedMu.Lock()
+	defer e.refreshedMu.Unlock()
+	for _, c := range collectors {
+		if c.enabled() && *c.refresh > 0 {
+			e.refreshed[c.name] = nil
+			go e.refresh(c)
+		}
+	}
+}
+
+// refresh runs c right away and then every collector.<name>.refresh.
+func (e *Exporter) refresh(c collector) {
+	for {
+		e.refreshCollector(c)
+		time.Sleep(*c.refresh)
+	}
+}
+
+func (e *Exporter) refreshCollector(c collector) {
+	db, err := e.connect()
+	if err != nil {
+		slog.Error("Error opening connection to database", "dsn", e.maskedDSN(), "err", err)
+		return
+	}
+	defer e.release(db)
+	metricCh := make(chan prometheus.Metric)
+	doneCh := make(chan struct{})
+	var metrics []prometheus.Metric
+	go func() {
+		for m := range metricCh {
+			metrics = append(metrics, m)
+		}
+		close(doneCh)
+	}()
+	begun := time.Now()
+	err = e.runCollector(context.Background(), c, db, metricCh)
+	close(metricCh)
+	<-doneCh
+	if err != nil {
+		slog.Error("Error refreshing collector", "collector", c.name, "err", err)
+		e.scrapeErrors.WithLabelValues(c.name).Inc()
+		if isConnectionLost(err) {
+			e.discardDB(db)
+		}
+	}
+	e.setStatus(c.name, time.Since(begun), err)
+
+	e.refreshedMu.Lock()
+	e.refreshed[c.name] = metrics
+	e.refreshedMu.Unlock()
+}
+
+// refreshedMetrics returns the metrics of the last background run of the
+// collector name, or false if it doesn't have a refresh interval.
+func (e *Exporter) refreshedMetrics(name string) ([]prometheus.Metric, bool) {
+	e.refreshedMu.Lock()
+	defer e.refreshedMu.Unlock()
+	metrics, ok := e.refreshed[name]
+	return metrics, ok
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRefreshers(t *testing.T) {
+	const refresh = 100 * time.Millisecond
+	var (
+		mu  sync.Mutex
+		ran []time.Time
+	)
+	runs := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(ran)
+	}
+	runsDesc := prometheus.NewDesc("oracledb_refreshed_runs", "Number of runs of the refreshed collector.", nil, nil)
+	interval := refresh
+	setCollectors(t,
+		collector{name: "refreshed", refresh: &interval, scrape: func(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+			mu.Lock()
+			ran = append(ran, time.Now())
+			n := len(ran)
+			mu.Unlock()
+			ch <- prometheus.MustNewConstMetric(runsDesc, prometheus.GaugeValue, float64(n))
+			return nil
+		}},
+	)
+	e := NewExporter(registerFakeDB(t, &fakeDB{queries: []fakeQuery{fakeUp}}))
+	e.startRefreshers()
+
+	waitRuns := func(n int) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); runs() < n; {
+			if time.Now().After(deadline) {
+				t.Fatalf("the collector ran %d times, want %d", runs(), n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		// Let the run be stored.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	waitRuns(1)
+	for i := 0; i < 3; i++ {
+		checkSamples(t, gather(t, uncheckedCollector{e}), `gauge oracledb_refreshed_runs{} 1`)
+	}
+	if got := runs(); got != 1 {
+		t.Errorf("the scrapes ran the collector, it ran %d times", got)
+	}
+
+	waitRuns(3)
+	// The runs are stored in order, so the scrape serves the latest one.
+	mu.Lock()
+	defer mu.Unlock()
+	checkSamples(t, gather(t, uncheckedCollector{e}), `gauge oracledb_refreshed_runs{} `+strconv.Itoa(len(ran)))
+	for i := 1; i < len(ran); i++ {
+		if d := ran[i].Sub(ran[i-1]); d < refresh {
+			t.Errorf("run %d came %v after the previous one, want at least %v", i+1, d, refresh)
+		}
+	}
+}
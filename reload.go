@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var customMetricsFile = flag.String("collector.custom-metrics-file", "", "File of custom gauges and table freshness checks added to those of the flags, one per line in the form \"custom-gauge name=query\" or \"table-freshness schema.table:column\". Reread on SIGHUP and on a POST to /-/reload.")
+
+// customMetrics are the custom gauges and table freshness checks.
+type customMetrics struct {
+	gauges customGaugesFlag
+	tables freshTablesFlag
+}
+
+var (
+	// customMetricsMu guards customGauges and freshTables, which are replaced
+	// when the custom metrics are reloaded.
+	customMetricsMu sync.RWMutex
+	// flagCustomMetrics are the custom metrics of the flags, which those of
+	// collector.custom-metrics-file are added to.
+	flagCustomMetrics customMetrics
+)
+
+// currentCustomMetrics returns the custom gauges and table freshness checks
+// in use. The returned slices are never modified.
+func currentCustomMetrics() customMetrics {
+	customMetricsMu.RLock()
+	defer customMetricsMu.RUnlock()
+	return customMetrics{gauges: *customGauges, tables: *freshTables}
+}
+
+// loadCustomMetrics adds the custom metrics of collector.custom-metrics-file
+// to those of the flags. It has to be called once after parsing the flags.
+func loadCustomMetrics() error {
+	flagCustomMetrics = currentCustomMetrics()
+	return reloadCustomMetrics()
+}
+
+// reloadCustomMetrics rereads collector.custom-metrics-file and replaces the
+// custom metrics it added by its current ones. If the file is invalid, the
+// custom metrics in use are kept.
+func reloadCustomMetrics() error {
+	m, err := readCustomMetrics(*customMetricsFile)
+	if err != nil {
+		return err
+	}
+	customMetricsMu.Lock()
+	old := customMetrics{gauges: *customGauges, tables: *freshTables}
+	*customGauges, *freshTables = m.gauges, m.tables
+	customMetricsMu.Unlock()
+
+	if *customMetricsFile == "" {
+		return nil
+	}
+	added, removed := diffCustomMetrics(old, m)
+	slog.Info("Loaded custom metrics", "file", *customMetricsFile, "gauges", len(m.gauges), "tables", len(m.tables), "added", strings.Join(added, ","), "removed", strings.Join(removed, ","))
+	return nil
+}
+
+// readCustomMetrics returns the custom metrics of the flags along with those
+// of the file path, if set. Empty lines and lines starting with # are ignored.
+func readCustomMetrics(path string) (customMetrics, error) {
+	m := customMetrics{
+		gauges: append(customGaugesFlag(nil), flagCustomMetrics.gauges...),
+		tables: append(freshTablesFlag(nil), flagCustomMetrics.tables...),
+	}
+	if path == "" {
+		return m, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return m, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var kind, value string
+		if i := strings.IndexAny(line, " \t"); i > 0 {
+			kind, value = line[:i], strings.TrimSpace(line[i:])
+		}
+		switch kind {
+		case "custom-gauge":
+			err = m.gauges.Set(value)
+		case "table-freshness":
+			err = m.tables.Set(value)
+		default:
+			err = fmt.Errorf("invalid line %q, must start with custom-gauge or table-freshness", line)
+		}
+		if err != nil {
+			return m, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+	}
+	return m, scanner.Err()
+}
+
+// diffCustomMetrics returns the custom gauges and tables of cur that are not
+// in old and the other way around. A gauge whose query changed is both.
+func diffCustomMetrics(old, cur customMetrics) (added, removed []string) {
+	keys := func(m customMetrics) map[string]bool {
+		keys := map[string]bool{}
+		for _, g := range m.gauges {
+			keys["custom-gauge "+g.name+"="+g.query] = true
+		}
+		for _, t := range m.tables {
+			keys["table-freshness "+t.schema+"."+t.table+":"+t.column] = true
+		}
+		return keys
+	}
+	oldKeys, curKeys := keys(old), keys(cur)
+	name := func(key string) string {
+		return strings.SplitN(key, "=", 2)[0]
+	}
+	for key := range curKeys {
+		if !oldKeys[key] {
+			added = append(added, name(key))
+		}
+	}
+	for key := range oldKeys {
+		if !curKeys[key] {
+			removed = append(removed, name(key))
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// reloadOnSignal reloads the custom metrics on every SIGHUP.
+func reloadOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := reloadCustomMetrics(); err != nil {
+			slog.Error("Error reloading custom metrics, keeping the current ones", "file", *customMetricsFile, "err", err)
+		}
+	}
+}
+
+// serveReload reloads the custom metrics on POST requests.
+func serveReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Only POST requests reload the custom metrics.", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := reloadCustomMetrics(); err != nil {
+		slog.Error("Error reloading custom metrics, keeping the current ones", "file", *customMetricsFile, "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"database/sql/driver"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReloadCustomMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom-metrics")
+	writeFile := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setFlag(t, "collector.custom-gauge", "app_orders=SELECT COUNT(*) FROM app.orders")
+	setFlag(t, "collector.custom-metrics-file", path)
+	t.Cleanup(func() {
+		*customGauges = nil
+		*freshTables = nil
+		flagCustomMetrics = customMetrics{}
+	})
+	writeFile("# Application metrics\n\ntable-freshness app.orders:created_at\n")
+	if err := loadCustomMetrics(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := openFakeDB(t,
+		fakeQuery{match: "FROM app.orders", columns: []string{"COUNT"}, rows: [][]driver.Value{{int64(3)}}},
+		fakeQuery{match: "FROM app.invoices", columns: []string{"COUNT"}, rows: [][]driver.Value{{int64(5)}}},
+	)
+	scrape := func() []string {
+		c := &scrapeCollector{scrape: ScrapeCustomGauges, db: db}
+		samples := gather(t, c)
+		if c.err != nil {
+			t.Fatal(c.err)
+		}
+		return samples
+	}
+	if got, want := scrape(), []string{`gauge oracledb_app_orders{} 3`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("before reloading got %q, want %q", got, want)
+	}
+
+	writeFile("custom-gauge app_invoices=SELECT COUNT(*) FROM app.invoices\n")
+	rec := httptest.NewRecorder()
+	serveReload(rec, httptest.NewRequest("POST", "/-/reload", nil))
+	if rec.Code != 200 {
+		t.Fatalf("reload failed with %d: %s", rec.Code, rec.Body)
+	}
+	want := []string{`gauge oracledb_app_invoices{} 5`, `gauge oracledb_app_orders{} 3`}
+	if got := scrape(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after reloading got %q, want %q", got, want)
+	}
+	if tables := currentCustomMetrics().tables; len(tables) != 0 {
+		t.Errorf("got tables %v after removing them from the file", tables)
+	}
+
+	// An invalid file, here redefining the gauge of the flag, is rejected.
+	writeFile("custom-gauge app_invoices=SELECT COUNT(*) FROM app.invoices\ncustom-gauge app_orders=SELECT 1 FROM DUAL\n")
+	rec = httptest.NewRecorder()
+	serveReload(rec, httptest.NewRequest("POST", "/-/reload", nil))
+	if rec.Code != 400 || !strings.Contains(rec.Body.String(), "custom-metrics:2: duplicate custom gauge name") {
+		t.Errorf("got %d: %s, want the invalid line to be rejected", rec.Code, rec.Body)
+	}
+	if got := scrape(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after failing to reload got %q, want the previous %q", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	serveReload(rec, httptest.NewRequest("GET", "/-/reload", nil))
+	if rec.Code != 405 {
+		t.Errorf("GET got %d, want 405", rec.Code)
+	}
+}
+
+func TestReadCustomMetricsInvalid(t *testing.T) {
+	for _, content := range []string{
+		"custom-gauge app_orders\n",
+		"table-freshness app.orders\n",
+		"gauge app_orders=SELECT 1 FROM DUAL\n",
+		"custom-gauge\n",
+	} {
+		path := filepath.Join(t.TempDir(), "custom-metrics")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if m, err := readCustomMetrics(path); err == nil {
+			t.Errorf("%q: got %+v, want an error", content, m)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// collectorStatus is the outcome of the last scrape of a collector, served as
+// JSON on /status.
+type collectorStatus struct {
+	Name                string     `json:"name"`
+	Enabled             bool       `json:"enabled"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastDurationSeconds float64    `json:"last_duration_seconds"`
+
+	// oraCode is the ora_code label of the collector's lastError series.
+	oraCode string
+}
+
+// setStatus records the outcome of a scrape of collector and replaces its
+// collector_last_error series by one for the ORA error code of err, or
+// removes it if err is nil.
+func (e *Exporter) setStatus(collector string, duration time.Duration, err error) {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+	s, ok := e.status[collector]
+	if !ok {
+		s = &collectorStatus{Name: collector}
+		e.status[collector] = s
+	}
+	s.LastDurationSeconds = duration.Seconds()
+	if s.oraCode != "" {
+		e.lastError.DeleteLabelValues(collector, s.oraCode)
+		s.oraCode = ""
+	}
+	if err == nil {
+		now := time.Now()
+		s.LastSuccess = &now
+		s.LastError = ""
+		return
+	}
+	s.LastError = err.Error()
+	s.oraCode = oraCode(err)
+	e.lastError.WithLabelValues(collector, s.oraCode).Set(1)
+}
+
+// ServeStatus serves the status of every collector as JSON.
+func (e *Exporter) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	e.statusMu.Lock()
+	statuses := make([]collectorStatus, 0, len(collectors))
+	for _, c := range collectors {
+		s := collectorStatus{Name: c.name}
+		if last, ok := e.status[c.name]; ok {
+			s = *last
+		}
+		s.Enabled = c.enabled()
+		statuses = append(statuses, s)
+	}
+	e.statusMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// exporterConfig is the effective configuration of the exporter, served as
+// JSON on /config.
+type exporterConfig struct {
+	DSN        string            `json:"dsn"`
+	Namespace  string            `json:"namespace"`
+	Collectors []string          `json:"enabled_collectors"`
+	Flags      map[string]string `json:"flags"`
+}
+
+// redactedFlags are the names, or prefixes of names, of the flags that
+// ServeConfig doesn't reveal the values of as they locate or authenticate to
+// the database or hold queries and table names.
+var redactedFlags = []string{"database.", "collector.custom-gauge", "collector.table-freshness"}
+
+// redacted reports whether the value of the flag name is redacted.
+func redacted(name string) bool {
+	for _, prefix := range redactedFlags {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeConfig serves the effective configuration as JSON, with the password
+// of the DSN masked and the values of redactedFlags replaced by "<redacted>"
+// unless they are the defaults.
+func (e *Exporter) ServeConfig(w http.ResponseWriter, r *http.Request) {
+	config := exporterConfig{
+		DSN:        e.maskedDSN(),
+		Namespace:  namespace,
+		Collectors: []string{},
+		Flags:      map[string]string{},
+	}
+	for _, c := range collectors {
+		if c.enabled() {
+			config.Collectors = append(config.Collectors, c.name)
+		}
+	}
+	// The custom metrics flags are replaced when reloading.
+	customMetricsMu.RLock()
+	defer customMetricsMu.RUnlock()
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if redacted(f.Name) && value != f.DefValue {
+			value = "<redacted>"
+		}
+		config.Flags[f.Name] = value
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(config); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeConfig(t *testing.T) {
+	// The flags holding lists aren't reset by setting their previous value.
+	t.Cleanup(func() {
+		*customGauges = nil
+		*freshTables = nil
+	})
+	setFlag(t, "database.user", "dbadmin")
+	setFlag(t, "database.password-file", "/run/secrets/oracle")
+	setFlag(t, "database.vault-path", "database/creds/exporter")
+	setFlag(t, "database.ssh-tunnel", "oracle@bastion:22")
+	setFlag(t, "collector.custom-gauge", "app_orders=SELECT COUNT(*) FROM app.orders")
+	setFlag(t, "collector.table-freshness", "app.orders:created_at")
+	setFlag(t, "collector.failed-logins.limit", "5")
+
+	rec := httptest.NewRecorder()
+	NewExporter("system/s3cret@dbhost:1521/ORCL").ServeConfig(rec, httptest.NewRequest("GET", "/config", nil))
+	body := rec.Body.String()
+	for _, secret := range []string{"s3cret", "dbadmin", "/run/secrets", "database/creds", "bastion", "app.orders"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("/config reveals %q:\n%s", secret, body)
+		}
+	}
+
+	var config exporterConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &config); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"database.user":                 "<redacted>",
+		"database.vault-path":           "<redacted>",
+		"collector.custom-gauge":        "<redacted>",
+		"collector.table-freshness":     "<redacted>",
+		"database.port":                 "1521",
+		"database.role":                 "normal",
+		"collector.failed-logins.limit": "5",
+	}
+	for name, value := range want {
+		if got := config.Flags[name]; got != value {
+			t.Errorf("flag %s is %q, want %q", name, got, value)
+		}
+	}
+	if config.DSN != "system/****@dbhost:1521/ORCL" {
+		t.Errorf("got DSN %q, want its password masked", config.DSN)
+	}
+}
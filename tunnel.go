@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	sshTunnel     = flag.String("database.ssh-tunnel", "", "SSH server in the form user@host:port to tunnel database connections through.")
+	sshKey        = flag.String("database.ssh-key", "", "Private key file to authenticate to the SSH server with.")
+	sshKnownHosts = flag.String("database.ssh-known-hosts", "", "known_hosts file to verify the SSH server's host key with. Defaults to ~/.ssh/known_hosts.")
+)
+
+// tunnel forwards connections accepted on a local listener to the database
+// through an SSH server. The SSH connection is re-established when it breaks.
+type tunnel struct {
+	addr   string
+	config *ssh.ClientConfig
+	remote string
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// openTunnel starts forwarding a local port to the database address of dsn
+// through the SSH server of database.ssh-tunnel and returns dsn rewritten to
+// connect to the local port.
+func openTunnel(dsn string) (string, error) {
+	user, addr, err := parseSSHTunnel(*sshTunnel)
+	if err != nil {
+		return "", err
+	}
+	key, err := os.ReadFile(*sshKey)
+	if err != nil {
+		return "", err
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s: %v", *sshKey, err)
+	}
+	knownHostsFile := *sshKnownHosts
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return "", err
+	}
+
+	// The listener's port isn't known yet, so rewrite with a placeholder to
+	// find out the remote address first.
+	_, remote, err := rewriteDSNAddress(dsn, "")
+	if err != nil {
+		return "", err
+	}
+	t := &tunnel{
+		addr: addr,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+		},
+		remote: remote,
+	}
+	if _, err := t.connect(); err != nil {
+		return "", err
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	go t.serve(l)
+	slog.Info("Tunneling database connections", "ssh_server", addr, "database", remote, "local", l.Addr().String())
+	dsn, _, err = rewriteDSNAddress(dsn, l.Addr().String())
+	return dsn, err
+}
+
+// parseSSHTunnel splits the database.ssh-tunnel flag into the user and the
+// address of the SSH server, defaulting to port 22.
+func parseSSHTunnel(s string) (user, addr string, err error) {
+	i := strings.LastIndex(s, "@")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", fmt.Errorf("invalid SSH tunnel %q, must be of the form user@host:port", s)
+	}
+	user, addr = s[:i], s[i+1:]
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+	return user, addr, nil
+}
+
+// rewriteDSNAddress replaces the host and port of an easy connect DSN such as
+// user/password@host:port/service by addr. It also returns the replaced
+// address, defaulting to port 1521. TNS aliases and connect descriptors can't
+// be rewritten.
+func rewriteDSNAddress(dsn, addr string) (string, string, error) {
+	at := strings.LastIndex(dsn, "@")
+	if at < 0 {
+		return "", "", fmt.Errorf("DSN has no database address to tunnel")
+	}
+	start := at + 1
+	if strings.HasPrefix(dsn[start:], "//") {
+		start += 2
+	}
+	end := len(dsn)
+	if i := strings.IndexAny(dsn[start:], "/?"); i >= 0 {
+		end = start + i
+	}
+	host := dsn[start:end]
+	if host == "" || strings.HasPrefix(host, "(") || (end == len(dsn) && !strings.Contains(host, ":")) {
+		return "", "", fmt.Errorf("only easy connect DSNs of the form host:port/service can be tunneled")
+	}
+	remote := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		remote = net.JoinHostPort(host, "1521")
+	}
+	return dsn[:start] + addr + dsn[end:], remote, nil
+}
+
+// connect returns the SSH client, dialing the SSH server if there is none.
+func (t *tunnel) connect() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := ssh.Dial("tcp", t.addr, t.config)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+	go func() {
+		err := client.Wait()
+		slog.Warn("SSH tunnel closed", "ssh_server", t.addr, "err", err)
+		t.mu.Lock()
+		t.client = nil
+		t.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// serve forwards every connection accepted on l to the database until l is
+// closed.
+func (t *tunnel) serve(l net.Listener) {
+	var delay time.Duration
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// Back off rather than spin while e.g. out of file descriptors,
+			// like net/http does.
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else if delay *= 2; delay > time.Second {
+				delay = time.Second
+			}
+			slog.Error("Error accepting tunneled connection", "err", err, "retry_in", delay)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+		go t.forward(local)
+	}
+}
+
+func (t *tunnel) forward(local net.Conn) {
+	defer local.Close()
+	client, err := t.connect()
+	if err != nil {
+		slog.Error("Error connecting to SSH server", "ssh_server", t.addr, "err", err)
+		return
+	}
+	remote, err := client.Dial("tcp", t.remote)
+	if err != nil {
+		slog.Error("Error connecting to database through SSH tunnel", "database", t.remote, "err", err)
+		return
+	}
+	defer remote.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
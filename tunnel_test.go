@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseSSHTunnel(t *testing.T) {
+	tests := []struct {
+		tunnel     string
+		user, addr string
+		err        bool
+	}{
+		{tunnel: "oracle@bastion:2222", user: "oracle", addr: "bastion:2222"},
+		{tunnel: "oracle@bastion", user: "oracle", addr: "bastion:22"},
+		{tunnel: "oracle@[::1]:2222", user: "oracle", addr: "[::1]:2222"},
+		{tunnel: "bastion:22", err: true},
+		{tunnel: "@bastion:22", err: true},
+		{tunnel: "oracle@", err: true},
+	}
+	for _, test := range tests {
+		user, addr, err := parseSSHTunnel(test.tunnel)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: got %q %q, want an error", test.tunnel, user, addr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.tunnel, err)
+		} else if user != test.user || addr != test.addr {
+			t.Errorf("%q: got %q %q, want %q %q", test.tunnel, user, addr, test.user, test.addr)
+		}
+	}
+}
+
+func TestRewriteDSNAddress(t *testing.T) {
+	tests := []struct {
+		dsn          string
+		want, remote string
+		err          bool
+	}{
+		{dsn: "system/oracle@dbhost:1521/ORCL", want: "system/oracle@127.0.0.1:4242/ORCL", remote: "dbhost:1521"},
+		{dsn: "system/oracle@dbhost/ORCL", want: "system/oracle@127.0.0.1:4242/ORCL", remote: "dbhost:1521"},
+		{dsn: "system/oracle@//dbhost:1522/ORCL", want: "system/oracle@//127.0.0.1:4242/ORCL", remote: "dbhost:1522"},
+		{dsn: "system/oracle@dbhost:1521", want: "system/oracle@127.0.0.1:4242", remote: "dbhost:1521"},
+		{dsn: "system/oracle@dbhost:1521?connect_timeout=5", want: "system/oracle@127.0.0.1:4242?connect_timeout=5", remote: "dbhost:1521"},
+		{dsn: "sys/p@ss@dbhost:1521/ORCL", want: "sys/p@ss@127.0.0.1:4242/ORCL", remote: "dbhost:1521"},
+		{dsn: "system/oracle", err: true},
+		{dsn: "system/oracle@ORCL", err: true},
+		{dsn: "system/oracle@(DESCRIPTION=(ADDRESS=(HOST=dbhost)(PORT=1521)))", err: true},
+	}
+	for _, test := range tests {
+		got, remote, err := rewriteDSNAddress(test.dsn, "127.0.0.1:4242")
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: got %q, want an error", test.dsn, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.dsn, err)
+		} else if got != test.want || remote != test.remote {
+			t.Errorf("%q: got %q to %q, want %q to %q", test.dsn, got, remote, test.want, test.remote)
+		}
+	}
+}
+
+// failingListener fails every Accept with a temporary error, and with
+// net.ErrClosed once failures errors have been returned.
+type failingListener struct {
+	net.Listener
+	failures, accepts int
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	l.accepts++
+	if l.accepts > l.failures {
+		return nil, net.ErrClosed
+	}
+	return nil, errors.New("accept tcp 127.0.0.1:4242: too many open files")
+}
+
+func TestTunnelServeStopsWhenClosed(t *testing.T) {
+	l := &failingListener{failures: 3}
+	done := make(chan struct{})
+	begun := time.Now()
+	go func() {
+		(&tunnel{}).serve(l)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve didn't return after the listener was closed")
+	}
+	if l.accepts != 4 {
+		t.Errorf("got %d accepts, want 4", l.accepts)
+	}
+	// 5ms, 10ms and 20ms.
+	if elapsed := time.Since(begun); elapsed < 35*time.Millisecond {
+		t.Errorf("serve retried within %v, want it to back off", elapsed)
+	}
+}
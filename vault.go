@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var vaultPath = flag.String("database.vault-path", "", "Vault path to read the database username and password from, e.g. database/creds/exporter. Vault is addressed by the VAULT_ADDR and VAULT_TOKEN environment variables.")
+
+var vaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// vaultSecret is the response of Vault to reading a database secrets engine
+// role or a version 1 key/value secret.
+type vaultSecret struct {
+	LeaseDuration int `json:"lease_duration"`
+	Data          struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// vaultDSN reads the credentials at database.vault-path from Vault and returns
+// dsn with them as its username and password, along with their lease.
+func vaultDSN(dsn string) (string, time.Duration, error) {
+	if !strings.Contains(dsn, "@") {
+		return "", 0, fmt.Errorf("DSN has no @ to add the credentials from Vault to")
+	}
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return "", 0, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	req, err := http.NewRequest(http.MethodGet, addr+"/v1/"+strings.TrimPrefix(*vaultPath, "/"), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	var secret vaultSecret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", 0, fmt.Errorf("decoding Vault response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("reading %s from Vault: %s: %s", *vaultPath, resp.Status, strings.Join(secret.Errors, ", "))
+	}
+	if secret.Data.Username == "" {
+		return "", 0, fmt.Errorf("Vault secret %s has no username", *vaultPath)
+	}
+	return dsnWithCredentials(dsn, secret.Data.Username, secret.Data.Password), time.Duration(secret.LeaseDuration) * time.Second, nil
+}
+
+// dsnWithCredentials replaces the username and password of dsn, which may also
+// be just the part from the @ on, e.g. @dbhost:1521/ORCL.
+func dsnWithCredentials(dsn, username, password string) string {
+	return username + "/" + password + dsn[strings.LastIndex(dsn, "@"):]
+}
+
+// refreshVaultCredentials reads new credentials from Vault before the lease of
+// the current ones expires and reconnects with them.
+func (e *Exporter) refreshVaultCredentials(dsn string, lease time.Duration) {
+	// Credentials without a lease, e.g. key/value secrets, never expire.
+	wait := lease * 2 / 3
+	for wait > 0 {
+		time.Sleep(wait)
+		newDSN, lease, err := vaultDSN(dsn)
+		if err != nil {
+			slog.Error("Error reading database credentials from Vault", "path", *vaultPath, "err", err)
+			wait = time.Minute
+			continue
+		}
+		e.setDSN(newDSN)
+		slog.Info("Refreshed database credentials from Vault", "path", *vaultPath, "lease", lease)
+		wait = lease * 2 / 3
+	}
+}
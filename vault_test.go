@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestVaultDSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		dsn    string
+		status int
+		body   string
+		want   string
+		lease  time.Duration
+		err    string
+	}{
+		{
+			name:   "database secret",
+			dsn:    "@dbhost:1521/ORCL",
+			status: http.StatusOK,
+			body:   `{"lease_duration":3600,"data":{"username":"v-exporter-1","password":"p@ss"}}`,
+			want:   "v-exporter-1/p@ss@dbhost:1521/ORCL",
+			lease:  time.Hour,
+		},
+		{
+			name:   "replaced credentials",
+			dsn:    "system/old@dbhost:1521/ORCL",
+			status: http.StatusOK,
+			body:   `{"data":{"username":"system","password":"new"}}`,
+			want:   "system/new@dbhost:1521/ORCL",
+		},
+		{
+			name:   "permission denied",
+			dsn:    "@dbhost:1521/ORCL",
+			status: http.StatusForbidden,
+			body:   `{"errors":["permission denied"]}`,
+			err:    "403 Forbidden: permission denied",
+		},
+		{
+			name:   "no username",
+			dsn:    "@dbhost:1521/ORCL",
+			status: http.StatusOK,
+			body:   `{"data":{}}`,
+			err:    "has no username",
+		},
+		{
+			name:   "invalid response",
+			dsn:    "@dbhost:1521/ORCL",
+			status: http.StatusOK,
+			body:   `<html>`,
+			err:    "decoding Vault response",
+		},
+		{
+			name: "DSN without @",
+			dsn:  "ORCL",
+			err:  "has no @",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/database/creds/exporter" {
+					t.Errorf("got request for %s", r.URL.Path)
+				}
+				if token := r.Header.Get("X-Vault-Token"); token != "t0ken" {
+					t.Errorf("got token %q", token)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer vault.Close()
+			t.Setenv("VAULT_ADDR", vault.URL+"/")
+			t.Setenv("VAULT_TOKEN", "t0ken")
+			setFlag(t, "database.vault-path", "/database/creds/exporter")
+
+			got, lease, err := vaultDSN(test.dsn)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want || lease != test.lease {
+				t.Errorf("got %q with lease %v, want %q with lease %v", got, lease, test.want, test.lease)
+			}
+		})
+	}
+}
+
+func TestVaultDSNNoAddr(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	if _, _, err := vaultDSN("@dbhost:1521/ORCL"); err == nil || !strings.Contains(err.Error(), "VAULT_ADDR") {
+		t.Errorf("got error %v, want VAULT_ADDR is not set", err)
+	}
+}
+
+// TestSetDSNDuringScrape checks that refreshed credentials don't close the pool
+// under a running scrape.
+func TestSetDSNDuringScrape(t *testing.T) {
+	oldDB, newDB := &fakeDB{queries: []fakeQuery{fakeUp}}, &fakeDB{queries: []fakeQuery{fakeUp}}
+	newDSN := registerFakeDB(t, newDB)
+	var e *Exporter
+	var scraped *sql.DB
+	setCollectors(t,
+		collector{name: "refreshing", scrape: func(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+			e.setDSN(newDSN)
+			return nil
+		}},
+		collector{name: "after", scrape: func(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+			scraped = db
+			return queryUp(ctx, db, "SELECT 1 FROM DUAL")
+		}},
+	)
+	e = NewExporter(registerFakeDB(t, oldDB))
+	checkSamples(t, gather(t, uncheckedCollector{e}),
+		`gauge oracledb_up{} 1`,
+		`gauge oracledb_exporter_last_scrape_error{} 0`,
+	)
+	if err := scraped.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Errorf("got %v pinging the previous pool after the scrape, want it closed", err)
+	}
+	if newDB.Opens() != 0 {
+		t.Errorf("got %d connections with the new DSN during the scrape, want 0", newDB.Opens())
+	}
+	gather(t, uncheckedCollector{e})
+	if newDB.Opens() != 1 {
+		t.Errorf("got %d connections with the new DSN after the scrape, want 1", newDB.Opens())
+	}
+}